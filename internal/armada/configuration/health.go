@@ -0,0 +1,10 @@
+package configuration
+
+// HealthCheckConfig is embedded in ArmadaConfig as HealthCheck.
+type HealthCheckConfig struct {
+	// Port the HTTP health endpoint (task.BackgroundTaskManager.HealthHandler)
+	// is served on, so a Kubernetes liveness probe can detect a wedged
+	// background task such as the event processor or lease expiry loop. 0
+	// disables the endpoint.
+	Port int
+}
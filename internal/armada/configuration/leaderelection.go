@@ -0,0 +1,25 @@
+package configuration
+
+import "time"
+
+// LeaderElectionConfig is embedded in ArmadaConfig as LeaderElection. When
+// Enabled is false, Serve behaves as before and runs every background
+// responsibility unconditionally - set this when only a single replica of
+// armada-server is deployed.
+type LeaderElectionConfig struct {
+	Enabled bool
+	// Backend selects the elector implementation: "redis" or "nats". Nats
+	// is only usable when EventsNats is also configured, since the elector
+	// opens its own connection using EventsNats' ClusterID and Servers.
+	Backend string
+
+	LeaseDuration time.Duration
+	RenewPeriod   time.Duration
+	RetryPeriod   time.Duration
+
+	// RedisKey is the key used to hold the lease when Backend is "redis".
+	RedisKey string
+	// NatsSubject is the subject claims are published on when Backend is
+	// "nats".
+	NatsSubject string
+}
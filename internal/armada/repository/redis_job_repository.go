@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+
+	"github.com/G-Research/armada/pkg/api"
+)
+
+const jobKeyPrefix = "Job:"
+
+// casScript atomically applies a job mutation only if the caller's expected
+// ResourceVersion still matches the version stored in redis, then bumps the
+// version - the same compare-and-swap shape used by the leader election
+// redis backend's lease renewal script.
+const casScript = `
+local current = redis.call("HGET", KEYS[1], "version")
+if current == false then
+	current = "0"
+end
+if current ~= ARGV[1] then
+	return {0, current}
+end
+redis.call("HSET", KEYS[1], "job", ARGV[2], "version", ARGV[3])
+return {1, ARGV[3]}`
+
+// ErrConflict is returned by the CAS update paths when the caller's expected
+// ResourceVersion no longer matches the version stored in redis - most often
+// because another armada-server replica (see the leader election work)
+// updated the job in the meantime.
+type ErrConflict struct {
+	JobId           string
+	ExpectedVersion int64
+	ActualVersion   int64
+}
+
+func (e *ErrConflict) Error() string {
+	return fmt.Sprintf("conflict updating job %s: expected resource version %d but it is %d", e.JobId, e.ExpectedVersion, e.ActualVersion)
+}
+
+// storedJob is the json value kept in the "job" hash field for every job. It
+// wraps api.Job; ResourceVersion itself lives in the sibling "version" hash
+// field so the CAS script can compare it without decoding the job.
+type storedJob struct {
+	Job *api.Job `json:"job"`
+}
+
+// JobRepository is the subset of RedisJobRepository's surface other armada
+// components depend on.
+type JobRepository interface {
+	GetJob(jobId string) (*api.Job, int64, error)
+	ReprioritiseJob(jobId string, newPriority float64, expectedVersion int64) (int64, error)
+	CancelJob(jobId string, expectedVersion int64) (int64, error)
+	UpdateJobWithRetry(jobId string, update func(job *api.Job) (*api.Job, error)) (*api.Job, error)
+}
+
+type RedisJobRepository struct {
+	db  redis.UniversalClient
+	cas *redis.Script
+}
+
+func NewRedisJobRepository(db redis.UniversalClient) *RedisJobRepository {
+	return &RedisJobRepository{
+		db:  db,
+		cas: redis.NewScript(casScript),
+	}
+}
+
+func jobKey(jobId string) string {
+	return jobKeyPrefix + jobId
+}
+
+func (repo *RedisJobRepository) GetJob(jobId string) (*api.Job, int64, error) {
+	result, err := repo.db.HMGet(jobKey(jobId), "job", "version").Result()
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	if result[0] == nil {
+		return nil, 0, errors.Errorf("no job found with id %s", jobId)
+	}
+
+	var stored storedJob
+	if err := json.Unmarshal([]byte(result[0].(string)), &stored); err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+
+	version := int64(0)
+	if result[1] != nil {
+		version, err = strconv.ParseInt(result[1].(string), 10, 64)
+		if err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+	}
+
+	return stored.Job, version, nil
+}
+
+// ReprioritiseJob sets job.Priority and writes it back only if expectedVersion
+// still matches what is stored, returning the new ResourceVersion on
+// success or ErrConflict otherwise.
+func (repo *RedisJobRepository) ReprioritiseJob(jobId string, newPriority float64, expectedVersion int64) (int64, error) {
+	return repo.casUpdate(jobId, expectedVersion, func(job *api.Job) (*api.Job, error) {
+		job.Priority = newPriority
+		return job, nil
+	})
+}
+
+// CancelJob marks the job cancelled, subject to the same CAS guard as
+// ReprioritiseJob.
+func (repo *RedisJobRepository) CancelJob(jobId string, expectedVersion int64) (int64, error) {
+	return repo.casUpdate(jobId, expectedVersion, func(job *api.Job) (*api.Job, error) {
+		job.Cancelled = true
+		return job, nil
+	})
+}
+
+// UpdateJobWithRetry re-reads the job, applies update and retries the CAS
+// write a bounded number of times if it loses a race with another writer -
+// analogous to Kubernetes' client-go GuaranteedUpdate. server.SubmitServer's
+// ReprioritiseJob/CancelJob RPCs and scheduling.LeaseManager.RenewLease use
+// this rather than calling casUpdate directly, since the client doesn't send
+// along the ResourceVersion it last observed. maxAttempts is set well above
+// the handful of concurrent writers a single job realistically sees, so
+// ordinary contention is retried away rather than surfaced as a conflict
+// error - it exists only to bound a pathologically stuck update.
+func (repo *RedisJobRepository) UpdateJobWithRetry(jobId string, update func(job *api.Job) (*api.Job, error)) (*api.Job, error) {
+	const maxAttempts = 20
+	const maxBackoff = time.Second
+	backoff := 10 * time.Millisecond
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		job, version, err := repo.GetJob(jobId)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := update(job)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = repo.casUpdate(jobId, version, func(*api.Job) (*api.Job, error) {
+			return updated, nil
+		})
+		if err == nil {
+			return updated, nil
+		}
+		if _, isConflict := err.(*ErrConflict); !isConflict {
+			return nil, err
+		}
+
+		lastErr = err
+		time.Sleep(jitter(backoff))
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	return nil, errors.Wrapf(lastErr, "giving up updating job %s after %d attempts", jobId, maxAttempts)
+}
+
+// jitter returns a random duration in [d/2, d], so writers that back off
+// from the same conflict don't all wake up and collide again in lockstep.
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (repo *RedisJobRepository) casUpdate(jobId string, expectedVersion int64, mutate func(job *api.Job) (*api.Job, error)) (int64, error) {
+	job, _, err := repo.GetJob(jobId)
+	if err != nil {
+		return 0, err
+	}
+
+	mutated, err := mutate(job)
+	if err != nil {
+		return 0, err
+	}
+
+	payload, err := json.Marshal(storedJob{Job: mutated})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	newVersion := expectedVersion + 1
+	result, err := repo.cas.Run(repo.db, []string{jobKey(jobId)}, expectedVersion, string(payload), newVersion).Result()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	values := result.([]interface{})
+	if values[0].(int64) == 0 {
+		actualVersion, _ := strconv.ParseInt(values[1].(string), 10, 64)
+		return 0, &ErrConflict{JobId: jobId, ExpectedVersion: expectedVersion, ActualVersion: actualVersion}
+	}
+
+	return newVersion, nil
+}
@@ -0,0 +1,55 @@
+package scheduling
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/G-Research/armada/internal/armada/repository"
+	"github.com/G-Research/armada/pkg/api"
+)
+
+// LeaseManager owns lease expiry and renewal for in-flight jobs. Only the
+// CAS-aware renewal path, RenewLease, does any work; ExpireLeases performs
+// no expiry scan.
+type LeaseManager struct {
+	jobRepository   repository.JobRepository
+	queueRepository repository.QueueRepository
+	eventStore      repository.EventStore
+	expireAfter     time.Duration
+}
+
+func NewLeaseManager(
+	jobRepository repository.JobRepository,
+	queueRepository repository.QueueRepository,
+	eventStore repository.EventStore,
+	expireAfter time.Duration,
+) *LeaseManager {
+	return &LeaseManager{
+		jobRepository:   jobRepository,
+		queueRepository: queueRepository,
+		eventStore:      eventStore,
+		expireAfter:     expireAfter,
+	}
+}
+
+// ExpireLeases is registered with the background task manager in
+// armada.Serve via RegisterWithContext and runs on
+// config.Scheduling.Lease.ExpiryLoopInterval; ctx is cancelled on shutdown.
+// Its body is empty: it does not look for jobs whose lease is older than
+// expireAfter and expires nothing, and returns immediately.
+func (m *LeaseManager) ExpireLeases(ctx context.Context) {
+}
+
+// RenewLease applies mutate to the job's stored lease state through
+// RedisJobRepository.UpdateJobWithRetry, so a renewal racing another
+// replica's renewal (or an expiry) of the same job re-reads and retries
+// instead of clobbering whichever write landed last.
+func (m *LeaseManager) RenewLease(jobId string, mutate func(job *api.Job) (*api.Job, error)) error {
+	_, err := m.jobRepository.UpdateJobWithRetry(jobId, mutate)
+	if err != nil {
+		log.Errorf("failed to renew lease for job %s: %v", jobId, err)
+	}
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -16,7 +17,9 @@ import (
 	"github.com/segmentio/kafka-go"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 
 	"github.com/G-Research/armada/internal/armada/authorization"
 	"github.com/G-Research/armada/internal/armada/configuration"
@@ -24,15 +27,31 @@ import (
 	"github.com/G-Research/armada/internal/armada/repository"
 	"github.com/G-Research/armada/internal/armada/scheduling"
 	"github.com/G-Research/armada/internal/armada/server"
+	"github.com/G-Research/armada/internal/common/leaderelection"
 	"github.com/G-Research/armada/internal/common/task"
 	"github.com/G-Research/armada/internal/common/util"
 	"github.com/G-Research/armada/pkg/api"
 )
 
+// schedulingMutationMethods lists the full gRPC method names that mutate
+// scheduling state. Followers reject these so clients can load-balance
+// Submit/Event/Usage traffic across every replica while scheduling itself is
+// only ever driven by the leader.
+var schedulingMutationMethods = map[string]bool{
+	"/api.AggregatedQueue/LeaseJobs":   true,
+	"/api.AggregatedQueue/RenewLease":  true,
+	"/api.AggregatedQueue/ReturnLease": true,
+	"/api.AggregatedQueue/ReportDone":  true,
+}
+
 func Serve(config *configuration.ArmadaConfig) (func(), *sync.WaitGroup) {
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
-	grpcServer := createServer(config)
+
+	electionCtx, cancelElection := context.WithCancel(context.Background())
+	elector, isLeader := createElector(electionCtx, config)
+
+	grpcServer := createServer(config, isLeader)
 
 	taskManager := task.NewBackgroundTaskManager(metrics.MetricPrefix)
 
@@ -67,7 +86,7 @@ func Serve(config *configuration.ArmadaConfig) (func(), *sync.WaitGroup) {
 		eventProcessor := repository.NewKafkaEventRedisProcessor(reader, redisEventRepository)
 
 		//TODO: Remove this metric, and add one to track event delay
-		taskManager.Register(eventProcessor.ProcessEvents, 100*time.Millisecond, "kafka_redis_processor")
+		taskManager.Register(leaderGated(eventProcessor.ProcessEvents, isLeader), 100*time.Millisecond, "kafka_redis_processor")
 
 	} else if len(config.EventsNats.Servers) > 0 {
 
@@ -80,7 +99,11 @@ func Serve(config *configuration.ArmadaConfig) (func(), *sync.WaitGroup) {
 			panic(err)
 		}
 		eventStore = repository.NewNatsEventStore(conn, config.EventsNats.Subject)
-		eventProcessor := repository.NewNatsEventRedisProcessor(conn, redisEventRepository, config.EventsNats.Subject, config.EventsNats.QueueGroup)
+		// isLeader gates delivery of each received message onto the redis
+		// event repository, so only the current leader actually processes
+		// the subscription; followers still receive messages (STAN requires
+		// an active subscription to track ack position) but drop them.
+		eventProcessor := repository.NewNatsEventRedisProcessor(conn, redisEventRepository, config.EventsNats.Subject, config.EventsNats.QueueGroup, isLeader)
 		eventProcessor.Start()
 
 		stopSubscription = func() {
@@ -102,14 +125,19 @@ func Serve(config *configuration.ArmadaConfig) (func(), *sync.WaitGroup) {
 	eventServer := server.NewEventServer(permissions, redisEventRepository, eventStore)
 	leaseManager := scheduling.NewLeaseManager(jobRepository, queueRepository, eventStore, config.Scheduling.Lease.ExpireAfter)
 
-	taskManager.Register(leaseManager.ExpireLeases, config.Scheduling.Lease.ExpiryLoopInterval, "lease_expiry")
+	taskManager.RegisterWithContext(leaderGatedWithContext(leaseManager.ExpireLeases, isLeader), config.Scheduling.Lease.ExpiryLoopInterval, "lease_expiry")
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", config.GrpcPort))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	metrics.ExposeDataMetrics(queueRepository, jobRepository, usageRepository)
+	// ExposeDataMetrics registers its collectors once (prometheus collectors
+	// can't be safely re-registered on every tick the way a regular
+	// taskManager-driven task can be re-run), but takes isLeader so the
+	// collectors themselves stop reporting data - rather than keep exposing
+	// stale values - the moment this replica loses the lease.
+	metrics.ExposeDataMetrics(queueRepository, jobRepository, usageRepository, isLeader)
 
 	api.RegisterSubmitServer(grpcServer, submitServer)
 	api.RegisterUsageServer(grpcServer, usageServer)
@@ -118,6 +146,33 @@ func Serve(config *configuration.ArmadaConfig) (func(), *sync.WaitGroup) {
 
 	grpc_prometheus.Register(grpcServer)
 
+	// Serves taskManager.HealthHandler so a Kubernetes liveness probe can
+	// detect a background task (the event processor, lease expiry) that has
+	// stopped ticking, rather than a replica quietly going stale forever.
+	stopHealthServer := func() {}
+	if config.HealthCheck.Port > 0 {
+		healthServer := &http.Server{
+			Addr:    fmt.Sprintf(":%d", config.HealthCheck.Port),
+			Handler: taskManager.HealthHandler(),
+		}
+		go func() {
+			if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Errorf("health check server failed: %v", err)
+			}
+		}()
+		stopHealthServer = func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := healthServer.Shutdown(shutdownCtx); err != nil {
+				log.Errorf("failed to shut down health check server: %v", err)
+			}
+		}
+	}
+
+	if elector != nil {
+		go elector.Run(electionCtx)
+	}
+
 	go func() {
 		defer log.Println("Stopping server.")
 
@@ -130,21 +185,89 @@ func Serve(config *configuration.ArmadaConfig) (func(), *sync.WaitGroup) {
 	}()
 
 	return func() {
+		cancelElection()
 		stopSubscription()
 		taskManager.StopAll(time.Second * 2)
+		stopHealthServer()
 		grpcServer.GracefulStop()
 	}, wg
 }
 
+// createElector builds the configured leader election backend and returns an
+// isLeader callback that can be handed to background tasks and the gRPC
+// interceptor. When leader election is disabled the callback always reports
+// true, so a single replica behaves exactly as it did before this package
+// existed.
+func createElector(ctx context.Context, config *configuration.ArmadaConfig) (leaderelection.Elector, func() bool) {
+	if !config.LeaderElection.Enabled {
+		return nil, func() bool { return true }
+	}
+
+	candidateId := "armada-server-" + util.NewULID()
+	electionConfig := leaderelection.Config{
+		LeaseDuration: config.LeaderElection.LeaseDuration,
+		RenewPeriod:   config.LeaderElection.RenewPeriod,
+		RetryPeriod:   config.LeaderElection.RetryPeriod,
+	}
+
+	switch config.LeaderElection.Backend {
+	case "redis":
+		client := createRedisClient(&config.Redis)
+		elector := leaderelection.NewRedisElector(client, config.LeaderElection.RedisKey, candidateId, electionConfig)
+		return elector, elector.IsLeader
+	case "nats":
+		conn, err := stan.Connect(
+			config.EventsNats.ClusterID,
+			candidateId,
+			stan.NatsURL(strings.Join(config.EventsNats.Servers, ",")),
+		)
+		if err != nil {
+			panic(err)
+		}
+		elector, err := leaderelection.NewNatsElector(conn, config.LeaderElection.NatsSubject, candidateId, electionConfig)
+		if err != nil {
+			panic(err)
+		}
+		return elector, elector.IsLeader
+	default:
+		log.Fatalf("unknown leader election backend %q", config.LeaderElection.Backend)
+		return nil, nil
+	}
+}
+
+// leaderGated wraps a task function registered with the background task
+// manager so it is a no-op on every replica except the current leader.
+func leaderGated(task func(), isLeader func() bool) func() {
+	return func() {
+		if isLeader() {
+			task()
+		}
+	}
+}
+
+// leaderGatedWithContext is leaderGated for tasks registered with
+// RegisterWithContext.
+func leaderGatedWithContext(task func(ctx context.Context), isLeader func() bool) func(context.Context) {
+	return func(ctx context.Context) {
+		if isLeader() {
+			task(ctx)
+		}
+	}
+}
+
 func createRedisClient(config *redis.UniversalOptions) redis.UniversalClient {
 	return redis.NewUniversalClient(config)
 }
 
-func createServer(config *configuration.ArmadaConfig) *grpc.Server {
+func createServer(config *configuration.ArmadaConfig, isLeader func() bool) *grpc.Server {
 
 	unaryInterceptors := []grpc.UnaryServerInterceptor{}
 	streamInterceptors := []grpc.StreamServerInterceptor{}
 
+	if config.LeaderElection.Enabled {
+		unaryInterceptors = append(unaryInterceptors, rejectSchedulingMutationsWhenNotLeader(isLeader))
+	}
+
 	authServices := []authorization.AuthService{}
 
 	if len(config.BasicAuth.Users) > 0 {
@@ -188,3 +311,16 @@ func createServer(config *configuration.ArmadaConfig) *grpc.Server {
 		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(streamInterceptors...)),
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unaryInterceptors...)))
 }
+
+// rejectSchedulingMutationsWhenNotLeader refuses the small set of RPCs that
+// mutate scheduling state on any replica that isn't currently the leader,
+// while leaving Submit/Event/Usage untouched so clients can keep
+// load-balancing read and submission traffic across every replica.
+func rejectSchedulingMutationsWhenNotLeader(isLeader func() bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if schedulingMutationMethods[info.FullMethod] && !isLeader() {
+			return nil, status.Errorf(codes.Unavailable, "this armada-server replica is not the leader, retry against the leader")
+		}
+		return handler(ctx, req, info)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/G-Research/armada/internal/armada/authorization"
+	"github.com/G-Research/armada/internal/armada/repository"
+	"github.com/G-Research/armada/pkg/api"
+)
+
+// SubmitServer implements api.SubmitServer. Only the job-mutation RPCs that
+// need to go through RedisJobRepository's compare-and-swap guard are defined
+// in this file - Submit/CreateQueue/UpdateQueue/DeleteQueue etc. live
+// alongside it in the rest of the package.
+type SubmitServer struct {
+	permissions     authorization.PermissionChecker
+	jobRepository   repository.JobRepository
+	queueRepository repository.QueueRepository
+	eventStore      repository.EventStore
+}
+
+func NewSubmitServer(
+	permissions authorization.PermissionChecker,
+	jobRepository repository.JobRepository,
+	queueRepository repository.QueueRepository,
+	eventStore repository.EventStore,
+) *SubmitServer {
+	return &SubmitServer{
+		permissions:     permissions,
+		jobRepository:   jobRepository,
+		queueRepository: queueRepository,
+		eventStore:      eventStore,
+	}
+}
+
+// ReprioritiseJob re-reads the job and retries the update if it loses a
+// race with another armada-server replica updating the same job (lease
+// renewal, a concurrent cancel, ...), instead of blindly overwriting
+// whatever is currently stored.
+func (s *SubmitServer) ReprioritiseJob(ctx context.Context, req *api.JobReprioritiseRequest) (*types.Empty, error) {
+	_, err := s.jobRepository.UpdateJobWithRetry(req.JobId, func(job *api.Job) (*api.Job, error) {
+		job.Priority = req.NewPriority
+		return job, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// CancelJob is subject to the same retry-on-conflict guard as
+// ReprioritiseJob.
+func (s *SubmitServer) CancelJob(ctx context.Context, req *api.JobCancelRequest) (*types.Empty, error) {
+	_, err := s.jobRepository.UpdateJobWithRetry(req.JobId, func(job *api.Job) (*api.Job, error) {
+		job.Cancelled = true
+		return job, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
@@ -0,0 +1,111 @@
+// Package leaderelection provides a small abstraction that lets several
+// armada-server replicas sit behind the same gRPC service while only one of
+// them performs background responsibilities such as lease expiry, event
+// processing and metric exposition.
+//
+// Replicas that are not currently the leader keep serving read-only RPCs but
+// must not run anything that mutates scheduling state.
+package leaderelection
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Elector is implemented by the NATS and Redis backed electors. Callers
+// should call Run once (it blocks until the passed context is cancelled) and
+// use IsLeader to react to leadership transitions.
+type Elector interface {
+	// Run executes the election loop until ctx is cancelled, renewing
+	// leadership (or retrying to acquire it) on the configured period.
+	Run(ctx context.Context)
+	// IsLeader reports whether this instance currently believes it holds
+	// the lease. It is safe to call concurrently from any goroutine.
+	IsLeader() bool
+}
+
+// status is embedded by both elector implementations to provide the
+// IsLeader bookkeeping so the NATS and Redis backends only need to
+// implement the acquisition/renewal logic itself.
+type status struct {
+	mutex    sync.RWMutex
+	isLeader bool
+}
+
+func newStatus() status {
+	return status{}
+}
+
+func (s *status) IsLeader() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.isLeader
+}
+
+func (s *status) set(isLeader bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.isLeader = isLeader
+}
+
+// Config is shared by every elector backend, regardless of how the lease
+// itself is stored.
+type Config struct {
+	// LeaseDuration is how long a claim is considered valid without being
+	// renewed. Followers treat a claim older than this as expired.
+	LeaseDuration time.Duration
+	// RenewPeriod is how often the leader re-publishes/renews its claim.
+	// Should be comfortably shorter than LeaseDuration.
+	RenewPeriod time.Duration
+	// RetryPeriod is how often a follower attempts to acquire leadership
+	// once it observes the current claim has expired.
+	RetryPeriod time.Duration
+	// ConfirmTimeout is only used by the NATS backend: after publishing a
+	// claim, it waits for its own subscription to actually deliver that
+	// exact claim back before accepting leadership (STAN's total subject
+	// ordering makes that deterministic, unlike guessing a delay). This is
+	// purely the safety-net ceiling on that wait, in case delivery never
+	// arrives (a dropped subscription, a stalled connection); it should be
+	// comfortably longer than normal delivery latency.
+	ConfirmTimeout time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = 15 * time.Second
+	}
+	if c.RenewPeriod <= 0 {
+		c.RenewPeriod = 5 * time.Second
+	}
+	if c.RetryPeriod <= 0 {
+		c.RetryPeriod = 2 * time.Second
+	}
+	if c.ConfirmTimeout <= 0 {
+		c.ConfirmTimeout = 5 * time.Second
+	}
+	return c
+}
+
+// runUntilCancelled is a small helper the backends use to drive their
+// acquire/renew loop without duplicating the timer plumbing in both
+// implementations. It ticks on Config.RenewPeriod while isLeader reports
+// true (the leader only needs to renew its own lease) and on the shorter
+// Config.RetryPeriod otherwise (a follower wants to notice an expired lease
+// quickly), re-evaluating which applies after every tick.
+func runUntilCancelled(ctx context.Context, config Config, isLeader func() bool, tick func()) {
+	tick()
+	for {
+		period := config.RetryPeriod
+		if isLeader() {
+			period = config.RenewPeriod
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(period):
+			tick()
+		}
+	}
+}
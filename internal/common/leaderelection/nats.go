@@ -0,0 +1,193 @@
+package leaderelection
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/nats-io/stan.go"
+	log "github.com/sirupsen/logrus"
+)
+
+// claim is published to the election subject every time a candidate takes or
+// renews the lease. Every candidate's subscription replays the subject from
+// the start, but only the most recent claim it has seen is kept; no history
+// is kept around.
+type claim struct {
+	CandidateID string    `json:"candidateId"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+func (c claim) expired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// NatsElector elects a leader over a durable STAN subject: candidates
+// subscribe to the subject to learn of the current claim, and race to
+// publish their own claim whenever it has expired. The winner keeps
+// publishing a refreshed claim (with a new TTL) on every renew period, so a
+// leader that is partitioned from the cluster simply stops renewing and a
+// follower takes over once the TTL lapses.
+//
+// A STAN subject is a single, strictly-ordered log and every subscriber
+// observes messages in the same order, so "the latest message on the
+// subject" is a consistent view across every replica. A candidate therefore
+// doesn't know it has won merely because Publish returned without error -
+// another candidate may have published around the same time - so after
+// publishing it waits for its own subscription to actually deliver that
+// exact claim back before accepting leadership: Publish only returns once
+// STAN has durably stored the message, and every subscriber (including our
+// own) observes the subject in that same storage order, so once our claim
+// has arrived, anything stored ahead of it - including a competitor's - has
+// necessarily arrived already. Guessing a fixed delay instead of waiting for
+// that delivery would let a slow subscription convince a candidate it had
+// won when it had not; see NatsElector.tick.
+type NatsElector struct {
+	status
+
+	conn    stan.Conn
+	subject string
+	id      string
+	config  Config
+
+	mutex    sync.Mutex
+	latest   claim
+	awaiting *claim
+	observed chan struct{}
+}
+
+// NewNatsElector returns an elector that contends for leadership by racing
+// to publish claim messages on subject. id should be stable for the lifetime
+// of the process.
+func NewNatsElector(conn stan.Conn, subject string, id string, config Config) (*NatsElector, error) {
+	e := &NatsElector{
+		status:  newStatus(),
+		conn:    conn,
+		subject: subject,
+		id:      id,
+		config:  config.withDefaults(),
+	}
+
+	_, err := conn.Subscribe(subject, func(msg *stan.Msg) {
+		var c claim
+		if err := json.Unmarshal(msg.Data, &c); err != nil {
+			log.Warnf("leaderelection: ignoring malformed claim on %s: %v", subject, err)
+			return
+		}
+
+		e.mutex.Lock()
+		e.latest = c
+		var observed chan struct{}
+		if e.awaiting != nil && e.awaiting.CandidateID == c.CandidateID && e.awaiting.ExpiresAt.Equal(c.ExpiresAt) {
+			observed = e.observed
+			e.awaiting = nil
+			e.observed = nil
+		}
+		e.mutex.Unlock()
+
+		if observed != nil {
+			close(observed)
+		}
+
+		if c.CandidateID != e.id {
+			e.set(false)
+		}
+	}, stan.DeliverAllAvailable())
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *NatsElector) Run(ctx context.Context) {
+	runUntilCancelled(ctx, e.config, e.IsLeader, func() {
+		e.tick()
+	})
+}
+
+func (e *NatsElector) getLatest() claim {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.latest
+}
+
+// awaitOwnClaim registers c as the claim tick is waiting to see delivered
+// back through our own subscription, and returns the channel that is closed
+// once that happens. Only one claim can be awaited at a time, which holds
+// because tick runs its publish/confirm cycle to completion before the next
+// one starts.
+func (e *NatsElector) awaitOwnClaim(c claim) <-chan struct{} {
+	ch := make(chan struct{})
+	e.mutex.Lock()
+	e.awaiting = &c
+	e.observed = ch
+	e.mutex.Unlock()
+	return ch
+}
+
+func (e *NatsElector) cancelAwait() {
+	e.mutex.Lock()
+	e.awaiting = nil
+	e.observed = nil
+	e.mutex.Unlock()
+}
+
+func (e *NatsElector) tick() {
+	if !e.IsLeader() && !e.getLatest().expired() {
+		// Someone else is holding a live claim, nothing to do until it
+		// expires.
+		return
+	}
+
+	c := claim{
+		CandidateID: e.id,
+		ExpiresAt:   time.Now().Add(e.config.LeaseDuration),
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		log.Errorf("leaderelection: failed to marshal claim: %v", err)
+		return
+	}
+
+	// Register the wait before publishing so the subscription callback
+	// cannot observe the claim and find nothing waiting for it.
+	confirmed := e.awaitOwnClaim(c)
+
+	if err := e.conn.Publish(e.subject, payload); err != nil {
+		log.Errorf("leaderelection: failed to publish claim on %s: %v", e.subject, err)
+		e.cancelAwait()
+		e.set(false)
+		return
+	}
+
+	// Publish succeeding only means the claim was durably appended to the
+	// subject, not that it's still the latest entry by the time our own
+	// subscription catches up - a competitor may have published a claim
+	// immediately before or after ours. Wait for our own subscription to
+	// actually deliver this exact claim back (rather than assuming a fixed
+	// delay was long enough) before deciding whether we actually won.
+	select {
+	case <-confirmed:
+	case <-time.After(e.config.ConfirmTimeout):
+		log.Errorf("leaderelection: timed out waiting to observe our own claim on %s, assuming we lost the race", e.subject)
+		e.cancelAwait()
+		e.set(false)
+		return
+	}
+
+	if latest := e.getLatest(); latest.CandidateID != e.id || !latest.ExpiresAt.Equal(c.ExpiresAt) {
+		// Another candidate's claim is now the most recent one on the
+		// subject - we lost the race.
+		e.set(false)
+		return
+	}
+
+	if !e.IsLeader() {
+		log.Infof("leaderelection: acquired nats lease %s as %s", e.subject, e.id)
+	}
+	e.set(true)
+}
+
+var _ Elector = (*NatsElector)(nil)
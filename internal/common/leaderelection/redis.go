@@ -0,0 +1,79 @@
+package leaderelection
+
+import (
+	"context"
+
+	"github.com/go-redis/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// renewScript atomically extends the lease only if it is still held by this
+// candidate, mirroring the classic Redis "SET with NX then CAS renew"
+// pattern so a stalled candidate can never steal back a lease another
+// instance has since (re)acquired.
+const renewScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+else
+	return 0
+end`
+
+// RedisElector elects a leader using a single Redis key: candidates race to
+// set it with NX/PX, and the current holder renews it with a Lua
+// compare-and-swap so only the instance whose ULID is still the stored value
+// can extend the lease.
+type RedisElector struct {
+	status
+
+	client redis.UniversalClient
+	key    string
+	id     string
+	config Config
+	renew  *redis.Script
+}
+
+// NewRedisElector returns an elector that contends for leadership of key
+// using client. id should be stable for the lifetime of the process (the
+// caller in armada.Serve uses util.NewULID()).
+func NewRedisElector(client redis.UniversalClient, key string, id string, config Config) *RedisElector {
+	return &RedisElector{
+		status: newStatus(),
+		client: client,
+		key:    key,
+		id:     id,
+		config: config.withDefaults(),
+		renew:  redis.NewScript(renewScript),
+	}
+}
+
+func (e *RedisElector) Run(ctx context.Context) {
+	runUntilCancelled(ctx, e.config, e.IsLeader, func() {
+		e.tick()
+	})
+}
+
+func (e *RedisElector) tick() {
+	if e.IsLeader() {
+		held, err := e.renew.Run(e.client, []string{e.key}, e.id, e.config.LeaseDuration.Milliseconds()).Result()
+		if err != nil {
+			log.Errorf("leaderelection: failed to renew redis lease %s: %v", e.key, err)
+			e.set(false)
+			return
+		}
+		e.set(held == int64(1))
+		return
+	}
+
+	ok, err := e.client.SetNX(e.key, e.id, e.config.LeaseDuration).Result()
+	if err != nil {
+		log.Errorf("leaderelection: failed to claim redis lease %s: %v", e.key, err)
+		e.set(false)
+		return
+	}
+	if ok {
+		log.Infof("leaderelection: acquired redis lease %s as %s", e.key, e.id)
+	}
+	e.set(ok)
+}
+
+var _ Elector = (*RedisElector)(nil)
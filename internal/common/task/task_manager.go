@@ -0,0 +1,234 @@
+// Package task runs background work (lease expiry, event processing, ...)
+// on a fixed interval for the lifetime of the process, recovering from
+// panics instead of letting one wedged task take the whole of
+// armada-server down with it.
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultBackoff = 5 * time.Second
+
+// RegisterOption customises a single Register/RegisterWithContext call.
+type RegisterOption func(*taskConfig)
+
+type taskConfig struct {
+	backoff      time.Duration
+	crashHandler func(name string, panicValue interface{})
+}
+
+// WithBackoff overrides the delay a task waits before its next tick after
+// recovering from a panic. Defaults to 5 seconds.
+func WithBackoff(d time.Duration) RegisterOption {
+	return func(c *taskConfig) { c.backoff = d }
+}
+
+// WithCrashHandler registers an additional callback invoked whenever the
+// task panics, alongside the built-in logging and metric.
+func WithCrashHandler(handler func(name string, panicValue interface{})) RegisterOption {
+	return func(c *taskConfig) { c.crashHandler = handler }
+}
+
+type taskState struct {
+	mutex       sync.RWMutex
+	interval    time.Duration
+	startedAt   time.Time
+	lastSuccess time.Time
+}
+
+func (s *taskState) recordSuccess(at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastSuccess = at
+}
+
+func (s *taskState) snapshot() (interval time.Duration, startedAt time.Time, lastSuccess time.Time) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.interval, s.startedAt, s.lastSuccess
+}
+
+// BackgroundTaskManager owns every registered background task and lets
+// armada.Serve stop them all together on shutdown.
+type BackgroundTaskManager struct {
+	wg     sync.WaitGroup
+	cancel []context.CancelFunc
+
+	tasksMutex sync.RWMutex
+	tasks      map[string]*taskState
+
+	panicCounter *prometheus.CounterVec
+}
+
+// NewBackgroundTaskManager creates a manager whose tasks' panics increment
+// metricPrefix+"task_panics_total". The counter is registered with the
+// default prometheus registry the first time this is called for a given
+// metricPrefix; later calls (e.g. tests constructing more than one manager
+// in the same process) reuse the already-registered collector instead of
+// panicking on a duplicate registration.
+func NewBackgroundTaskManager(metricPrefix string) *BackgroundTaskManager {
+	panicCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: metricPrefix + "task_panics_total",
+			Help: "Number of panics recovered from background tasks, by task name.",
+		},
+		[]string{"task"},
+	)
+	if err := prometheus.Register(panicCounter); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			panicCounter = are.ExistingCollector.(*prometheus.CounterVec)
+		} else {
+			log.Fatalf("failed to register background task panic counter: %v", err)
+		}
+	}
+
+	return &BackgroundTaskManager{
+		tasks:        map[string]*taskState{},
+		panicCounter: panicCounter,
+	}
+}
+
+// Register runs task every interval until StopAll is called, recovering and
+// logging any panic instead of letting it crash the process.
+func (m *BackgroundTaskManager) Register(task func(), interval time.Duration, name string, opts ...RegisterOption) {
+	m.RegisterWithContext(func(context.Context) { task() }, interval, name, opts...)
+}
+
+// RegisterWithContext is like Register, but task is passed a context that is
+// cancelled when StopAll is called, so a task blocked on a Kafka read or a
+// redis call can observe shutdown instead of being abandoned.
+func (m *BackgroundTaskManager) RegisterWithContext(task func(ctx context.Context), interval time.Duration, name string, opts ...RegisterOption) {
+	cfg := taskConfig{backoff: defaultBackoff}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	state := &taskState{interval: interval, startedAt: time.Now()}
+
+	m.tasksMutex.Lock()
+	m.tasks[name] = state
+	m.cancel = append(m.cancel, cancel)
+	m.tasksMutex.Unlock()
+
+	m.wg.Add(1)
+	go m.run(ctx, task, interval, name, cfg, state)
+}
+
+func (m *BackgroundTaskManager) run(ctx context.Context, task func(ctx context.Context), interval time.Duration, name string, cfg taskConfig, state *taskState) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if m.tick(ctx, task, name, cfg) {
+				state.recordSuccess(time.Now())
+			} else {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(cfg.backoff):
+				}
+			}
+		}
+	}
+}
+
+// tick runs task once, recovering from and reporting any panic. It returns
+// false if the task panicked, so run knows to back off before the next tick
+// rather than hammering a task that is reliably crashing.
+func (m *BackgroundTaskManager) tick(ctx context.Context, task func(ctx context.Context), name string, cfg taskConfig) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("recovered from panic in background task %s: %v\n%s", name, r, debug.Stack())
+			m.panicCounter.WithLabelValues(name).Inc()
+			if cfg.crashHandler != nil {
+				cfg.crashHandler(name, r)
+			}
+			ok = false
+		}
+	}()
+
+	task(ctx)
+	return true
+}
+
+// StopAll cancels every registered task and waits up to timeout for them to
+// return.
+func (m *BackgroundTaskManager) StopAll(timeout time.Duration) {
+	m.tasksMutex.RLock()
+	cancelFuncs := m.cancel
+	m.tasksMutex.RUnlock()
+
+	for _, cancel := range cancelFuncs {
+		cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Warnf("timed out after %s waiting for background tasks to stop", timeout)
+	}
+}
+
+// healthStatus is the per-task payload served by HealthHandler.
+type healthStatus struct {
+	LastSuccess time.Time `json:"lastSuccess"`
+	Healthy     bool      `json:"healthy"`
+}
+
+// HealthHandler reports the last successful tick per registered task, so it
+// can be wired up as a Kubernetes liveness probe that fires if an event
+// processor or the lease expiry loop gets wedged. A task counts as healthy
+// until it has missed three consecutive ticks; a task that has never
+// ticked successfully is only given that same three-tick grace period from
+// when it was registered, not forever, so one that panics on every single
+// tick is correctly reported unhealthy instead of looking perpetually fine.
+func (m *BackgroundTaskManager) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.tasksMutex.RLock()
+		statuses := make(map[string]healthStatus, len(m.tasks))
+		allHealthy := true
+		for name, state := range m.tasks {
+			interval, startedAt, lastSuccess := state.snapshot()
+
+			since := lastSuccess
+			if since.IsZero() {
+				since = startedAt
+			}
+			healthy := time.Since(since) < interval*3
+
+			allHealthy = allHealthy && healthy
+			statuses[name] = healthStatus{LastSuccess: lastSuccess, Healthy: healthy}
+		}
+		m.tasksMutex.RUnlock()
+
+		if !allHealthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(statuses); err != nil {
+			log.Errorf("failed to encode background task health response: %v", err)
+		}
+	})
+}
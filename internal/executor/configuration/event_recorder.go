@@ -0,0 +1,13 @@
+package configuration
+
+// EventRecorderConfig controls whether the executor publishes native
+// Kubernetes Events for managed pod lifecycle transitions, in addition to
+// reporting upstream to armada-server.
+type EventRecorderConfig struct {
+	Enabled bool
+	// QPS and Burst bound how many Events per second the recorder will emit
+	// before dropping them, so a high-churn queue cannot flood the
+	// apiserver with Tainted/Failed/Preempted events.
+	QPS   float32
+	Burst int
+}
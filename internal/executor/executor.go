@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/G-Research/k8s-batch/internal/armada/api"
+	"github.com/G-Research/k8s-batch/internal/executor/configuration"
+	"github.com/G-Research/k8s-batch/internal/executor/service"
+	"k8s.io/client-go/kubernetes"
+	lister "k8s.io/client-go/listers/core/v1"
+)
+
+// StartUpConfig bundles the subset of the executor's configuration needed to
+// wire up cluster utilisation reporting, tainted-pod reconciliation and pod
+// lifecycle event recording.
+type StartUpConfig struct {
+	ClusterId                   string
+	ReportingInterval           time.Duration
+	TaintedPodReconcileInterval time.Duration
+	TaintedPodReasons           []service.TaintedPodReasonConfig
+	PodPhaseReportingInterval   time.Duration
+	EventRecorder               configuration.EventRecorderConfig
+}
+
+// StartUp constructs ClusterUtilisationService, TaintedPodController and,
+// when event recording is enabled, PodPhaseEventReporter, and runs each of
+// their reconciliation loops on its own interval for the lifetime of the
+// executor process. It returns a function that stops every loop it started.
+func StartUp(
+	kubernetesClient kubernetes.Interface,
+	usageClient api.UsageClient,
+	eventReporter service.EvictionEventReporter,
+	podLister lister.PodLister,
+	nodeLister lister.NodeLister,
+	config StartUpConfig,
+) func() {
+	clusterUtilisationService := service.ClusterUtilisationService{
+		ClientId:    config.ClusterId,
+		PodLister:   podLister,
+		NodeLister:  nodeLister,
+		UsageClient: usageClient,
+	}
+
+	var podEventRecorder *service.PodEventRecorder
+	var podPhaseEventReporter *service.PodPhaseEventReporter
+	if config.EventRecorder.Enabled {
+		podEventRecorder = service.NewPodEventRecorder(kubernetesClient.CoreV1(), config.EventRecorder)
+		podPhaseEventReporter = service.NewPodPhaseEventReporter(podLister, podEventRecorder)
+	}
+
+	taintedPodController := service.TaintedPodController{
+		ClientId:         config.ClusterId,
+		KubernetesClient: kubernetesClient,
+		PodLister:        podLister,
+		NodeLister:       nodeLister,
+		EventReporter:    eventReporter,
+		PodEventRecorder: podEventRecorder,
+		Reasons:          config.TaintedPodReasons,
+	}
+
+	stopUtilisationReporting := runOnInterval(config.ReportingInterval, clusterUtilisationService.ReportClusterUtilisation)
+	stopTaintedPodReconciliation := runOnInterval(config.TaintedPodReconcileInterval, taintedPodController.ReconcileTaintedPods)
+
+	stopPodPhaseReporting := func() {}
+	if podPhaseEventReporter != nil {
+		stopPodPhaseReporting = runOnInterval(config.PodPhaseReportingInterval, podPhaseEventReporter.ReportPodPhaseTransitions)
+	}
+
+	return func() {
+		stopUtilisationReporting()
+		stopTaintedPodReconciliation()
+		stopPodPhaseReporting()
+	}
+}
+
+// runOnInterval runs task every interval in its own goroutine until the
+// returned stop function is called, which blocks until the goroutine has
+// actually exited.
+func runOnInterval(interval time.Duration, task func()) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				task()
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
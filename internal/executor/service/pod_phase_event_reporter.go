@@ -0,0 +1,137 @@
+package service
+
+import (
+	"github.com/G-Research/k8s-batch/internal/executor/util"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	lister "k8s.io/client-go/listers/core/v1"
+)
+
+// podPhase is a coarser, Armada-specific view of a managed pod's progress
+// than v1.PodPhase alone gives: it splits the pre-scheduling states Leased
+// (the executor has taken ownership of the pod object) and Assigned (it has
+// a node) out of what v1.PodPhase otherwise lumps together as Pending.
+type podPhase string
+
+const (
+	podPhaseLeased    podPhase = "Leased"
+	podPhaseAssigned  podPhase = "Assigned"
+	podPhaseRunning   podPhase = "Running"
+	podPhaseSucceeded podPhase = "Succeeded"
+	podPhaseFailed    podPhase = "Failed"
+	podPhasePreempted podPhase = "Preempted"
+)
+
+// PodPhaseEventReporter is the state machine PodEventRecorder's
+// Leased/Assigned/Running/Succeeded/Failed/Preempted methods are wired
+// into: on every reconcile it diffs each managed pod's current podPhase
+// against the last one reported for it, and fires the matching event
+// exactly once per transition, the same way TaintedPodController stamps a
+// pod once per broken condition rather than on every poll.
+type PodPhaseEventReporter struct {
+	PodLister        lister.PodLister
+	PodEventRecorder *PodEventRecorder
+
+	lastReportedPhase map[types.UID]podPhase
+}
+
+// NewPodPhaseEventReporter returns a reporter ready to have
+// ReportPodPhaseTransitions run on an interval, the same way executor.StartUp
+// drives ClusterUtilisationService and TaintedPodController.
+func NewPodPhaseEventReporter(podLister lister.PodLister, podEventRecorder *PodEventRecorder) *PodPhaseEventReporter {
+	return &PodPhaseEventReporter{
+		PodLister:         podLister,
+		PodEventRecorder:  podEventRecorder,
+		lastReportedPhase: map[types.UID]podPhase{},
+	}
+}
+
+// ReportPodPhaseTransitions emits a PodEventRecorder event for every managed
+// pod that has moved into a new podPhase since the last reconcile.
+func (r *PodPhaseEventReporter) ReportPodPhaseTransitions() {
+	pods, err := r.PodLister.List(util.GetManagedPodSelector())
+	if err != nil {
+		log.Errorf("Failed to reconcile pod phase events because %s", err)
+		return
+	}
+
+	seen := make(map[types.UID]bool, len(pods))
+	for _, pod := range pods {
+		seen[pod.UID] = true
+		r.reportTransition(pod)
+	}
+
+	// A pod stops being returned by the lister once it is deleted (which
+	// follows shortly after a terminal phase is reported), so anything no
+	// longer seen can't transition any further - drop it rather than
+	// growing the map without bound.
+	for uid := range r.lastReportedPhase {
+		if !seen[uid] {
+			delete(r.lastReportedPhase, uid)
+		}
+	}
+}
+
+func (r *PodPhaseEventReporter) reportTransition(pod *v1.Pod) {
+	phase := currentPodPhase(pod)
+	if r.lastReportedPhase[pod.UID] == phase {
+		return
+	}
+	r.lastReportedPhase[pod.UID] = phase
+
+	switch phase {
+	case podPhaseLeased:
+		r.PodEventRecorder.Leased(pod)
+	case podPhaseAssigned:
+		r.PodEventRecorder.Assigned(pod)
+	case podPhaseRunning:
+		r.PodEventRecorder.Running(pod)
+	case podPhaseSucceeded:
+		r.PodEventRecorder.Succeeded(pod)
+	case podPhaseFailed:
+		r.PodEventRecorder.Failed(pod, podFailureReason(pod))
+	case podPhasePreempted:
+		r.PodEventRecorder.Preempted(pod)
+	}
+}
+
+func currentPodPhase(pod *v1.Pod) podPhase {
+	if isPreempted(pod) {
+		return podPhasePreempted
+	}
+
+	switch pod.Status.Phase {
+	case v1.PodSucceeded:
+		return podPhaseSucceeded
+	case v1.PodFailed:
+		return podPhaseFailed
+	case v1.PodRunning:
+		return podPhaseRunning
+	}
+
+	if pod.Spec.NodeName != "" {
+		return podPhaseAssigned
+	}
+
+	return podPhaseLeased
+}
+
+// isPreempted matches the Status.Reason the kubelet stamps on a pod it
+// terminates to make room for a higher priority one, so preemption is
+// reported distinctly from an ordinary container failure.
+func isPreempted(pod *v1.Pod) bool {
+	return pod.Status.Reason == "Preempted"
+}
+
+func podFailureReason(pod *v1.Pod) string {
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if terminated := containerStatus.State.Terminated; terminated != nil && terminated.Reason != "" {
+			return terminated.Reason
+		}
+	}
+	return "Pod failed"
+}
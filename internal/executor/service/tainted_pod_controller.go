@@ -0,0 +1,273 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/G-Research/k8s-batch/internal/executor/domain"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	lister "k8s.io/client-go/listers/core/v1"
+)
+
+// EvictionEventReporter reports a tainted-pod eviction back to armada-server
+// over the existing event pipeline, the same way ClusterUtilisationService
+// reports usage over its UsageClient.
+type EvictionEventReporter interface {
+	ReportTaintedPodEviction(jobId string, queue string, podName string, reason string) error
+}
+
+const (
+	taintedTimestampAnnotation       = "armada/tainted-timestamp"
+	taintedReasonAnnotation          = "armada/tainted-reason"
+	taintedPreventEvictionAnnotation = "armada/tainted-prevent-eviction"
+)
+
+// TaintedPodReasonConfig configures a single broken-but-non-terminal
+// condition the TaintedPodController watches for: the signal(s) that
+// identify it and the grace period a pod is allowed to remain in that
+// condition before it is evicted. Detection is entirely table-driven off
+// this struct, so operators can tune thresholds, add a new condition or
+// disable one (by leaving it out of the list) without any code change.
+type TaintedPodReasonConfig struct {
+	// Reason is stamped into the tainted-reason annotation and reported
+	// alongside the eviction.
+	Reason string
+	// ContainerWaitingReasons are the kubelet container waiting reasons
+	// (container.State.Waiting.Reason, e.g. "ImagePullBackOff") that mark a
+	// pod as broken for this Reason.
+	ContainerWaitingReasons []string
+	// NodeNotReady marks a pod as broken for this Reason once it is bound
+	// to a node that is missing from the lister or not Ready.
+	NodeNotReady bool
+	GracePeriod  time.Duration
+}
+
+// TaintedPodController evicts managed pods that have become stuck in a
+// broken but non-terminal state - for example wedged in ContainerCreating,
+// stuck in an image pull backoff, or orphaned on a node that has gone
+// NotReady while the pod is still bound to it. Left alone these pods would
+// never progress and never free up the resource they are holding.
+type TaintedPodController struct {
+	ClientId         string
+	KubernetesClient kubernetes.Interface
+	PodLister        lister.PodLister
+	NodeLister       lister.NodeLister
+	EventReporter    EvictionEventReporter
+	// PodEventRecorder is optional; when set, every eviction also gets a
+	// native Tainted Kubernetes Event alongside the upstream JobRunErrors
+	// event reported through EventReporter.
+	PodEventRecorder *PodEventRecorder
+	Reasons          []TaintedPodReasonConfig
+}
+
+// ReconcileTaintedPods stamps newly-broken pods with the tainted-* metadata
+// and evicts any previously stamped pod whose reason-specific grace period
+// has elapsed. executor.StartUp runs it on its own interval, alongside
+// ClusterUtilisationService.ReportClusterUtilisation.
+func (c TaintedPodController) ReconcileTaintedPods() {
+	c.validateReasons()
+
+	pods, err := getAllActiveManagedPods(c.PodLister)
+	if err != nil {
+		log.Errorf("Failed to reconcile tainted pods because %s", err)
+		return
+	}
+
+	nodesByName, err := c.getNodesByName()
+	if err != nil {
+		log.Errorf("Failed to reconcile tainted pods because %s", err)
+		return
+	}
+
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			// Already evicted and terminating. The lister keeps returning it
+			// until it actually disappears, so without this the eviction and
+			// its upstream/k8s events would re-fire on every reconcile for as
+			// long as it lingers in that state.
+			continue
+		}
+		if pod.Annotations[taintedPreventEvictionAnnotation] == "true" {
+			continue
+		}
+
+		reason, broken := c.detectBrokenCondition(pod, nodesByName)
+
+		taintedAt, alreadyTainted := pod.Annotations[taintedTimestampAnnotation]
+		switch {
+		case broken && !alreadyTainted:
+			c.stampTainted(pod, reason)
+		case broken && alreadyTainted:
+			c.evictIfGracePeriodElapsed(pod, reason, taintedAt)
+		case !broken && alreadyTainted:
+			c.clearTaint(pod)
+		}
+	}
+}
+
+// detectBrokenCondition is entirely table-driven off c.Reasons: it never
+// hard-codes a reason string, so a new broken condition is added purely by
+// configuration. A Reasons entry with no detection criteria at all is logged
+// by validateReasons; an entry whose criteria are set but never match a pod
+// is not reported.
+func (c TaintedPodController) detectBrokenCondition(pod *v1.Pod, nodesByName map[string]*v1.Node) (string, bool) {
+	waitingReasons := containerWaitingReasons(pod)
+	for _, r := range c.Reasons {
+		for _, waiting := range waitingReasons {
+			if containsString(r.ContainerWaitingReasons, waiting) {
+				return r.Reason, true
+			}
+		}
+	}
+
+	if pod.Spec.NodeName != "" {
+		node, present := nodesByName[pod.Spec.NodeName]
+		if !present || !isNodeReady(node) {
+			for _, r := range c.Reasons {
+				if r.NodeNotReady {
+					return r.Reason, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// validateReasons logs (rather than silently ignoring) any configured
+// reason that can never be detected, e.g. a copy-paste error that leaves
+// both ContainerWaitingReasons empty and NodeNotReady false.
+func (c TaintedPodController) validateReasons() {
+	for _, r := range c.Reasons {
+		if len(r.ContainerWaitingReasons) == 0 && !r.NodeNotReady {
+			log.Errorf("Tainted pod reason %q has no detection criteria configured, it will never be stamped or evicted", r.Reason)
+		}
+	}
+}
+
+func containerWaitingReasons(pod *v1.Pod) []string {
+	reasons := make([]string, 0, len(pod.Status.ContainerStatuses))
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if waiting := containerStatus.State.Waiting; waiting != nil {
+			reasons = append(reasons, waiting.Reason)
+		}
+	}
+	return reasons
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (c TaintedPodController) gracePeriod(reason string) (time.Duration, bool) {
+	for _, r := range c.Reasons {
+		if r.Reason == reason {
+			return r.GracePeriod, true
+		}
+	}
+	return 0, false
+}
+
+func (c TaintedPodController) stampTainted(pod *v1.Pod, reason string) {
+	if _, configured := c.gracePeriod(reason); !configured {
+		return
+	}
+
+	patched := pod.DeepCopy()
+	if patched.Annotations == nil {
+		patched.Annotations = map[string]string{}
+	}
+	patched.Annotations[taintedTimestampAnnotation] = fmt.Sprintf("%d", time.Now().Unix())
+	patched.Annotations[taintedReasonAnnotation] = reason
+
+	if _, err := c.KubernetesClient.CoreV1().Pods(pod.Namespace).Update(patched); err != nil {
+		log.Errorf("Failed to stamp tainted pod %s: %s", pod.Name, err)
+	}
+}
+
+func (c TaintedPodController) clearTaint(pod *v1.Pod) {
+	patched := pod.DeepCopy()
+	delete(patched.Annotations, taintedTimestampAnnotation)
+	delete(patched.Annotations, taintedReasonAnnotation)
+
+	if _, err := c.KubernetesClient.CoreV1().Pods(pod.Namespace).Update(patched); err != nil {
+		log.Errorf("Failed to clear tainted annotations on pod %s: %s", pod.Name, err)
+	}
+}
+
+func (c TaintedPodController) evictIfGracePeriodElapsed(pod *v1.Pod, reason string, taintedAtAnnotation string) {
+	gracePeriod, configured := c.gracePeriod(reason)
+	if !configured {
+		return
+	}
+
+	var taintedAtUnix int64
+	if _, err := fmt.Sscanf(taintedAtAnnotation, "%d", &taintedAtUnix); err != nil {
+		log.Errorf("Pod %s has an unparseable %s annotation, re-stamping: %s", pod.Name, taintedTimestampAnnotation, err)
+		c.stampTainted(pod, reason)
+		return
+	}
+
+	taintedAt := time.Unix(taintedAtUnix, 0)
+	if time.Now().Before(taintedAt.Add(gracePeriod)) {
+		return
+	}
+
+	eviction := &policy.Eviction{
+		ObjectMeta: pod.ObjectMeta,
+	}
+	if err := c.KubernetesClient.PolicyV1beta1().Evictions(pod.Namespace).Evict(eviction); err != nil {
+		log.Errorf("Failed to evict tainted pod %s: %s", pod.Name, err)
+		return
+	}
+
+	log.Warnf("Evicted tainted pod %s after %s in state %s", pod.Name, gracePeriod, reason)
+	if c.PodEventRecorder != nil {
+		c.PodEventRecorder.Tainted(pod, reason)
+	}
+	c.reportTaintedEviction(pod, reason)
+}
+
+func (c TaintedPodController) reportTaintedEviction(pod *v1.Pod, reason string) {
+	jobId, present := pod.Labels[domain.JobId]
+	if !present {
+		log.Errorf("Evicted tainted pod %s has no job id label, not reporting event", pod.Name)
+		return
+	}
+	queue := pod.Labels[domain.Queue]
+
+	if err := c.EventReporter.ReportTaintedPodEviction(jobId, queue, pod.Name, reason); err != nil {
+		log.Errorf("Failed to report eviction of tainted pod %s: %s", pod.Name, err)
+	}
+}
+
+func (c TaintedPodController) getNodesByName() (map[string]*v1.Node, error) {
+	nodes, err := c.NodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	nodesByName := make(map[string]*v1.Node, len(nodes))
+	for _, node := range nodes {
+		nodesByName[node.Name] = node
+	}
+	return nodesByName, nil
+}
+
+func isNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}